@@ -0,0 +1,260 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package executer allows execute Pending association and InProgress association
+package executer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/plugin"
+)
+
+// PluginResult is the structured, per-plugin counterpart of a line in the legacy summary
+// string: what happened to a single plugin, with enough detail that a caller no longer has
+// to regex-parse English to find out.
+type PluginResult struct {
+	Status    contracts.ResultStatus `json:"status"`
+	StartedAt time.Time              `json:"startedAt,omitempty"`
+	EndedAt   time.Time              `json:"endedAt,omitempty"`
+	ExitCode  int                    `json:"exitCode"`
+	Output    string                 `json:"output,omitempty"`
+}
+
+// RunSummary is the machine-readable result of a single association run, replacing the
+// free-form string buildOutput used to produce. It is computed from the same
+// map[string]*contracts.PluginRuntimeStatus every caller already has; nothing new needs to be
+// threaded through to populate it other than timing/exit-code data plugins already report in
+// contracts.PluginRuntimeStatus.
+type RunSummary struct {
+	TotalPlugins int                     `json:"totalPlugins"`
+	Processed    int                     `json:"processed"`
+	Success      int                     `json:"success"`
+	Failed       int                     `json:"failed"`
+	TimedOut     int                     `json:"timedOut"`
+	Skipped      int                     `json:"skipped"`
+	PerPlugin    map[string]PluginResult `json:"perPlugin"`
+}
+
+// String renders the same human-readable line the executer has always produced, so existing
+// consumers (association reporter, MDS reply, on-disk state) that expect English keep working
+// unmodified while new consumers can use the RunSummary struct directly.
+func (s RunSummary) String() string {
+	pluginWord := "plugins"
+	if s.TotalPlugins == 1 {
+		pluginWord = "plugin"
+	}
+
+	return fmt.Sprintf(
+		"%v out of %v %v processed, %v success, %v failed, %v timedout, %v skipped",
+		s.Processed, s.TotalPlugins, pluginWord, s.Success, s.Failed, s.TimedOut, s.Skipped)
+}
+
+// MarshalJSON satisfies json.Marshaler explicitly so RunSummary's JSON shape is documented here
+// rather than left to struct-tag inference alone.
+func (s RunSummary) MarshalJSON() ([]byte, error) {
+	type alias RunSummary
+	return json.Marshal(alias(s))
+}
+
+// buildSummary tallies pluginOutputs into a RunSummary. totalNumberOfActions may exceed
+// len(pluginOutputs) when some requested plugins never produced a status at all (e.g. the
+// agent was asked for a plugin it doesn't recognize).
+func buildSummary(pluginOutputs map[string]*contracts.PluginRuntimeStatus, totalNumberOfActions int) RunSummary {
+	summary := RunSummary{
+		TotalPlugins: totalNumberOfActions,
+		PerPlugin:    make(map[string]PluginResult, len(pluginOutputs)),
+	}
+
+	for name, status := range pluginOutputs {
+		if status == nil {
+			continue
+		}
+
+		summary.PerPlugin[name] = PluginResult{
+			Status:    status.Status,
+			StartedAt: status.StartDateTime,
+			EndedAt:   status.EndDateTime,
+			ExitCode:  status.Code,
+			Output:    status.Output,
+		}
+
+		switch status.Status {
+		case contracts.ResultStatusSkipped:
+			summary.Processed++
+			summary.Skipped++
+		case contracts.ResultStatusTimedOut:
+			summary.Processed++
+			summary.TimedOut++
+		case contracts.ResultStatusFailed:
+			summary.Processed++
+			summary.Failed++
+		case contracts.ResultStatusSuccess, contracts.ResultStatusPassedAndReboot:
+			summary.Processed++
+			summary.Success++
+		default:
+			summary.Processed++
+		}
+	}
+
+	return summary
+}
+
+// overallStatus picks a single contracts.ResultStatus representative of the whole run: failed
+// if anything failed, timed out if anything timed out (and nothing failed), success otherwise.
+func overallStatus(summary RunSummary) contracts.ResultStatus {
+	switch {
+	case summary.Failed > 0:
+		return contracts.ResultStatusFailed
+	case summary.TimedOut > 0:
+		return contracts.ResultStatusTimedOut
+	default:
+		return contracts.ResultStatusSuccess
+	}
+}
+
+// buildOutput produces the legacy human-readable summary line plus an overall status, e.g.
+// "4 out of 5 plugins processed, 2 success, 1 failed, 0 timedout, 1 skipped". It is kept for
+// backward compatibility with callers that only want the string; new callers should prefer
+// buildSummary/Summary for the structured RunSummary.
+func buildOutput(pluginOutputs map[string]*contracts.PluginRuntimeStatus, totalNumberOfActions int) (string, contracts.ResultStatus) {
+	summary := buildSummary(pluginOutputs, totalNumberOfActions)
+	return summary.String(), overallStatus(summary)
+}
+
+// PluginExecuter executes an association's plugins and keeps the RunSummary of its most recent
+// run available for programmatic consumers, alongside the legacy log line it still emits.
+//
+// It also subscribes to the plugin package's lifecycle event bus so a plugin the registry
+// reports as Skipped can be distinguished in the summary: "skipped because the document didn't
+// ask for it" versus "skipped because the plugin failed to load/was disabled", per
+// plugin.EventFailed/plugin.EventDisabled.
+type PluginExecuter struct {
+	mu      sync.RWMutex
+	summary RunSummary
+
+	unavailableMu sync.RWMutex
+	unavailable   map[string]error
+
+	sub  *plugin.Subscription
+	stop chan struct{}
+}
+
+// NewPluginExecuter creates a PluginExecuter and subscribes it to the plugin event bus. Callers
+// must call Close when done to release the subscription.
+func NewPluginExecuter() *PluginExecuter {
+	e := &PluginExecuter{
+		unavailable: map[string]error{},
+		sub:         plugin.Events("", ""),
+		stop:        make(chan struct{}),
+	}
+
+	go e.watchPluginAvailability()
+
+	return e
+}
+
+// watchPluginAvailability records the most recent reason a plugin became unavailable, so a
+// subsequent Skipped status for that plugin can be explained in the summary instead of just
+// reported as skipped.
+func (e *PluginExecuter) watchPluginAvailability() {
+	for {
+		select {
+		case evt, ok := <-e.sub.C():
+			if !ok {
+				return
+			}
+			if evt.Type != plugin.EventFailed && evt.Type != plugin.EventDisabled {
+				continue
+			}
+
+			e.unavailableMu.Lock()
+			e.unavailable[evt.PluginName] = evt.Err
+			e.unavailableMu.Unlock()
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// Close stops watching the plugin event bus and releases the underlying subscription. Safe to
+// call more than once.
+func (e *PluginExecuter) Close() {
+	select {
+	case <-e.stop:
+		// already closed
+	default:
+		close(e.stop)
+	}
+	e.sub.Close()
+}
+
+// buildSummary is like the package-level buildSummary, but fills in the reason a Skipped plugin
+// was actually unavailable, learned from the plugin event bus, whenever the plugin itself didn't
+// already report one, and publishes plugin.EventStarted/plugin.EventStopped for every plugin that
+// actually ran, using the StartedAt/EndedAt/Status it already reported.
+func (e *PluginExecuter) buildSummary(pluginOutputs map[string]*contracts.PluginRuntimeStatus, totalNumberOfActions int) RunSummary {
+	summary := buildSummary(pluginOutputs, totalNumberOfActions)
+
+	e.unavailableMu.RLock()
+	defer e.unavailableMu.RUnlock()
+
+	for name, result := range summary.PerPlugin {
+		if result.Status != contracts.ResultStatusSkipped {
+			var runErr error
+			if result.Status == contracts.ResultStatusFailed || result.Status == contracts.ResultStatusTimedOut {
+				runErr = fmt.Errorf("plugin %s: %s", name, result.Status)
+			}
+			plugin.NotifyPluginRun(name, result.StartedAt, result.EndedAt, runErr)
+			continue
+		}
+
+		if result.Output != "" {
+			continue
+		}
+
+		if err, ok := e.unavailable[name]; ok {
+			result.Output = fmt.Sprintf("skipped: plugin unavailable (%v)", err)
+			summary.PerPlugin[name] = result
+		}
+	}
+
+	return summary
+}
+
+// Run builds the RunSummary for a completed set of plugin results, records it so Summary()
+// returns it, and returns the legacy human-readable line and overall status for callers (the
+// association reporter, MDS reply, on-disk state) that haven't moved to RunSummary yet.
+func (e *PluginExecuter) Run(pluginOutputs map[string]*contracts.PluginRuntimeStatus, totalNumberOfActions int) (string, contracts.ResultStatus) {
+	summary := e.buildSummary(pluginOutputs, totalNumberOfActions)
+	e.record(summary)
+	return summary.String(), overallStatus(summary)
+}
+
+// record stores summary as the result of the most recent run, for Summary() to return.
+func (e *PluginExecuter) record(summary RunSummary) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.summary = summary
+}
+
+// Summary returns the RunSummary of the most recent association run handled by e.
+func (e *PluginExecuter) Summary() RunSummary {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.summary
+}
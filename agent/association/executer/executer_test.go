@@ -43,6 +43,15 @@ func TestOutputBuilderWithMultiplePlugins(t *testing.T) {
 	fmt.Println(output)
 	assert.NotNil(t, output)
 	assert.Equal(t, output, "4 out of 5 plugins processed, 2 success, 1 failed, 0 timedout, 1 skipped")
+
+	summary := buildSummary(results, 5)
+	assert.Equal(t, 5, summary.TotalPlugins)
+	assert.Equal(t, 4, summary.Processed)
+	assert.Equal(t, 2, summary.Success)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 0, summary.TimedOut)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, output, summary.String())
 }
 
 func TestOutputBuilderWithSinglePlugin(t *testing.T) {
@@ -57,6 +66,10 @@ func TestOutputBuilderWithSinglePlugin(t *testing.T) {
 	fmt.Println(output)
 	assert.NotNil(t, output)
 	assert.Equal(t, output, "1 out of 1 plugin processed, 0 success, 1 failed, 0 timedout, 0 skipped")
+
+	summary := buildSummary(results, 1)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, output, summary.String())
 }
 
 func TestOutputBuilderWithSinglePluginWithSkippedStatus(t *testing.T) {
@@ -71,4 +84,29 @@ func TestOutputBuilderWithSinglePluginWithSkippedStatus(t *testing.T) {
 	fmt.Println(output)
 	assert.NotNil(t, output)
 	assert.Equal(t, output, "1 out of 1 plugin processed, 0 success, 0 failed, 0 timedout, 1 skipped")
+
+	summary := buildSummary(results, 1)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, output, summary.String())
+}
+
+func TestPluginExecuterRunRecordsSummary(t *testing.T) {
+	results := make(map[string]*contracts.PluginRuntimeStatus)
+	results["pluginA"] = &contracts.PluginRuntimeStatus{
+		Status: contracts.ResultStatusSuccess,
+	}
+	results["pluginB"] = &contracts.PluginRuntimeStatus{
+		Status: contracts.ResultStatusSkipped,
+	}
+
+	e := NewPluginExecuter()
+	defer e.Close()
+
+	output, status := e.Run(results, 2)
+
+	assert.Equal(t, "2 out of 2 plugins processed, 1 success, 0 failed, 0 timedout, 1 skipped", output)
+	assert.Equal(t, contracts.ResultStatusSuccess, status)
+	assert.Equal(t, e.Summary().String(), output)
+	assert.Equal(t, 1, e.Summary().Success)
+	assert.Equal(t, 1, e.Summary().Skipped)
 }
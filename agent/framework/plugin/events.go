@@ -0,0 +1,159 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened to a plugin.
+type EventType string
+
+const (
+	// EventLoaded fires once a plugin handler has been constructed and registered.
+	EventLoaded EventType = "Loaded"
+	// EventEnabled fires when a plugin transitions to StateEnabled.
+	EventEnabled EventType = "Enabled"
+	// EventDisabled fires when a plugin transitions to StateDisabled.
+	EventDisabled EventType = "Disabled"
+	// EventFailed fires when a plugin could not be loaded at all.
+	EventFailed EventType = "Failed"
+	// EventStarted fires when a plugin begins executing a document step.
+	EventStarted EventType = "Started"
+	// EventStopped fires when a plugin finishes executing a document step, successfully or not.
+	// There is no separate EventCrashed: a plugin's runpluginutil.T.Execute runs in-process and
+	// reports its outcome through contracts.PluginRuntimeStatus.Status like any other result,
+	// rather than crashing the agent, so there's no distinct crash signal to fire this on.
+	EventStopped EventType = "Stopped"
+)
+
+// Event describes a single lifecycle transition for a named plugin.
+type Event struct {
+	Type       EventType
+	PluginName string
+	Timestamp  time.Time
+	Err        error
+}
+
+// eventBufferSize bounds how many events a slow subscriber can fall behind by before new
+// events for it are dropped rather than blocking the publisher.
+const eventBufferSize = 64
+
+// subscription is one consumer's view of the bus, optionally filtered.
+type subscription struct {
+	id         int
+	ch         chan Event
+	pluginName string // empty means "all plugins"
+	eventType  EventType
+	anyType    bool // true means "all event types"
+}
+
+var (
+	busMu     sync.RWMutex
+	subs      = map[int]*subscription{}
+	nextSubID int
+)
+
+// Subscription is a handle on a live Events() subscription. Callers must Close it once they're
+// done consuming, or the subscription (and its buffered channel) leaks for the life of the
+// process - this matters for long-lived subscribers like a health monitor or an executer that
+// resubscribes across reloads/runs.
+type Subscription struct {
+	sub *subscription
+}
+
+// C returns the channel of matching events for this subscription.
+func (s *Subscription) C() <-chan Event {
+	return s.sub.ch
+}
+
+// Close unsubscribes from the bus and closes the channel returned by C. Safe to call more than
+// once.
+func (s *Subscription) Close() {
+	busMu.Lock()
+	defer busMu.Unlock()
+	if _, ok := subs[s.sub.id]; !ok {
+		return
+	}
+	delete(subs, s.sub.id)
+	close(s.sub.ch)
+}
+
+// Events subscribes to plugin lifecycle events, optionally filtered by plugin name and/or
+// event type. Pass "" for pluginName or "" for eventType to match everything on that axis.
+// Delivery is buffered and non-blocking: if a subscriber falls behind, further events are
+// dropped for that subscriber rather than stalling plugin loads. Callers must Close the
+// returned Subscription when done to release it.
+func Events(pluginName string, eventType EventType) *Subscription {
+	busMu.Lock()
+	defer busMu.Unlock()
+
+	sub := &subscription{
+		id:         nextSubID,
+		ch:         make(chan Event, eventBufferSize),
+		pluginName: pluginName,
+		eventType:  eventType,
+		anyType:    eventType == "",
+	}
+
+	subs[sub.id] = sub
+	nextSubID++
+
+	return &Subscription{sub: sub}
+}
+
+// notifyLoadResult publishes EventLoaded or EventFailed for a plugin that was just constructed
+// by loadWorkerPlugins/loadLongRunningPlugins, based on whether construction succeeded.
+func notifyLoadResult(pluginName string, err error) {
+	evt := Event{PluginName: pluginName, Timestamp: time.Now(), Err: err}
+	if err != nil {
+		evt.Type = EventFailed
+	} else {
+		evt.Type = EventLoaded
+	}
+	publish(evt)
+}
+
+// NotifyPluginRun publishes EventStarted followed by EventStopped for a single plugin's
+// completed execution, using the start/end times and error the caller already has. It exists
+// because the actual plugin invocation happens in association/executer, not in this package: that
+// package only learns a plugin's outcome once its Execute call has already returned, so there's
+// no earlier point to fire EventStarted from and no separate crash signal distinct from err.
+func NotifyPluginRun(pluginName string, start, end time.Time, err error) {
+	publish(Event{Type: EventStarted, PluginName: pluginName, Timestamp: start})
+	publish(Event{Type: EventStopped, PluginName: pluginName, Timestamp: end, Err: err})
+}
+
+// publish delivers an event to every subscriber whose filter matches. It never blocks: a
+// subscriber whose buffer is full simply misses the event.
+func publish(evt Event) {
+	busMu.RLock()
+	defer busMu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.pluginName != "" && sub.pluginName != evt.PluginName {
+			continue
+		}
+		if !sub.anyType && sub.eventType != evt.Type {
+			continue
+		}
+
+		select {
+		case sub.ch <- evt:
+		default:
+			// subscriber is behind; drop rather than block the publisher
+		}
+	}
+}
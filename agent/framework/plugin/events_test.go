@@ -0,0 +1,126 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsFiltersByPluginNameAndType(t *testing.T) {
+	subA := Events("pluginA", EventLoaded)
+	defer subA.Close()
+
+	subAny := Events("", "")
+	defer subAny.Close()
+
+	publish(Event{Type: EventLoaded, PluginName: "pluginA", Timestamp: time.Now()})
+	publish(Event{Type: EventLoaded, PluginName: "pluginB", Timestamp: time.Now()})
+	publish(Event{Type: EventFailed, PluginName: "pluginA", Timestamp: time.Now()})
+
+	select {
+	case evt := <-subA.C():
+		assert.Equal(t, "pluginA", evt.PluginName)
+		assert.Equal(t, EventLoaded, evt.Type)
+	case <-time.After(time.Second):
+		t.Fatal("expected a filtered event for pluginA/EventLoaded")
+	}
+
+	select {
+	case evt := <-subA.C():
+		t.Fatalf("did not expect a second event on the filtered subscription, got %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	received := 0
+	for i := 0; i < 3; i++ {
+		select {
+		case <-subAny.C():
+			received++
+		case <-time.After(time.Second):
+			t.Fatal("expected the unfiltered subscription to see every published event")
+		}
+	}
+	assert.Equal(t, 3, received)
+}
+
+func TestSubscriptionCloseStopsDelivery(t *testing.T) {
+	sub := Events("pluginA", "")
+	sub.Close()
+
+	publish(Event{Type: EventLoaded, PluginName: "pluginA", Timestamp: time.Now()})
+
+	_, ok := <-sub.C()
+	assert.False(t, ok, "C() should be closed/empty after Close")
+}
+
+func TestSubscriptionCloseIsIdempotent(t *testing.T) {
+	sub := Events("pluginA", "")
+	sub.Close()
+	assert.NotPanics(t, func() { sub.Close() })
+}
+
+func TestNotifyLoadResultPublishesLoadedOrFailed(t *testing.T) {
+	subLoaded := Events("pluginA", EventLoaded)
+	defer subLoaded.Close()
+	subFailed := Events("pluginB", EventFailed)
+	defer subFailed.Close()
+
+	notifyLoadResult("pluginA", nil)
+	notifyLoadResult("pluginB", assert.AnError)
+
+	select {
+	case evt := <-subLoaded.C():
+		assert.Equal(t, EventLoaded, evt.Type)
+		assert.NoError(t, evt.Err)
+	case <-time.After(time.Second):
+		t.Fatal("expected EventLoaded for a successful load")
+	}
+
+	select {
+	case evt := <-subFailed.C():
+		assert.Equal(t, EventFailed, evt.Type)
+		assert.Equal(t, assert.AnError, evt.Err)
+	case <-time.After(time.Second):
+		t.Fatal("expected EventFailed for a failed load")
+	}
+}
+
+func TestNotifyPluginRunPublishesStartedThenStopped(t *testing.T) {
+	sub := Events("pluginA", "")
+	defer sub.Close()
+
+	start := time.Now()
+	end := start.Add(time.Second)
+	NotifyPluginRun("pluginA", start, end, assert.AnError)
+
+	var got []Event
+	for i := 0; i < 2; i++ {
+		select {
+		case evt := <-sub.C():
+			got = append(got, evt)
+		case <-time.After(time.Second):
+			t.Fatal("expected both EventStarted and EventStopped")
+		}
+	}
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, EventStarted, got[0].Type)
+	assert.True(t, got[0].Timestamp.Equal(start))
+	assert.Equal(t, EventStopped, got[1].Type)
+	assert.True(t, got[1].Timestamp.Equal(end))
+	assert.Equal(t, assert.AnError, got[1].Err)
+}
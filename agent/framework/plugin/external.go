@@ -0,0 +1,268 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"plugin"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/aws/amazon-ssm-agent/agent/plugins/pluginutil"
+)
+
+// externalPluginFilePattern matches <name>_plugin.so, capturing the plugin name.
+var externalPluginFilePattern = regexp.MustCompile(`^(.+)_plugin\.so$`)
+
+// newSSMPluginSymbol is the symbol every external plugin .so must export.
+const newSSMPluginSymbol = "NewSSMPlugin"
+
+// NewSSMPluginFunc is the signature an external plugin's NewSSMPlugin symbol must match.
+type NewSSMPluginFunc func(context context.T, config pluginutil.PluginConfig) (runpluginutil.T, error)
+
+// wrapPlugin caches an opened external plugin so repeated scans don't redo any work for a file
+// that hasn't changed: modTime is compared against the source file on disk to detect a .so
+// rebuilt in place under the same filename. loadPath is the path actually passed to plugin.Open
+// to load it, which is NOT path - see the comment on ExternalLoader.loadDir for why.
+type wrapPlugin struct {
+	handler  runpluginutil.T
+	path     string
+	modTime  time.Time
+	loadPath string
+}
+
+// ExternalLoader walks a directory for *_plugin.so files and registers each one it can open
+// under the plugin name embedded in its filename, letting operators ship site-specific plugins
+// (custom inventory collectors, bespoke domain-join flows) without forking the agent.
+type ExternalLoader struct {
+	context context.T
+	dir     string
+
+	// loadDir holds a private copy of every .so this loader has opened, under a name unique to
+	// that file's modTime. plugin.Open caches opened plugins process-globally keyed on the
+	// canonical path it was given, and never re-dlopens that same path even if the underlying
+	// file's content changes - so reopening dir's own path on a rebuild would silently keep
+	// serving the old code. Copying each version to its own path in loadDir first sidesteps that
+	// cache entirely, because every rebuild gets a path plugin.Open has never seen before.
+	loadDir string
+
+	mu      sync.RWMutex
+	plugins map[string]*wrapPlugin
+
+	sighupCh chan os.Signal
+	stop     chan struct{}
+}
+
+// NewExternalLoader creates a loader that will scan dir when LoadExternalPlugins or
+// ReloadPlugins is called.
+func NewExternalLoader(context context.T, dir string) *ExternalLoader {
+	return &ExternalLoader{
+		context: context,
+		dir:     dir,
+		plugins: map[string]*wrapPlugin{},
+	}
+}
+
+// LoadExternalPlugins scans the loader's directory for the first time, opening and registering
+// every matching *_plugin.so file it finds, and starts watching SIGHUP to rescan automatically
+// from then on. Call ReloadPlugins directly if a caller wants to force a rescan outside of
+// SIGHUP (e.g. from ssm-cli).
+func (l *ExternalLoader) LoadExternalPlugins() (runpluginutil.PluginRegistry, error) {
+	registry, err := l.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	l.watchSIGHUP()
+
+	return registry, nil
+}
+
+// ReloadPlugins rescans the loader's directory without requiring an agent restart, picking up
+// new or updated *_plugin.so files.
+func (l *ExternalLoader) ReloadPlugins() (runpluginutil.PluginRegistry, error) {
+	return l.scan()
+}
+
+// watchSIGHUP starts a background goroutine that calls ReloadPlugins every time the process
+// receives SIGHUP, logging (but not failing on) scan errors. Safe to call more than once; only
+// the first call installs the signal handler.
+func (l *ExternalLoader) watchSIGHUP() {
+	l.mu.Lock()
+	if l.sighupCh != nil {
+		l.mu.Unlock()
+		return
+	}
+	l.sighupCh = make(chan os.Signal, 1)
+	l.stop = make(chan struct{})
+	l.mu.Unlock()
+
+	signal.Notify(l.sighupCh, syscall.SIGHUP)
+
+	go func() {
+		log := l.context.Log()
+		for {
+			select {
+			case <-l.sighupCh:
+				log.Infof("received SIGHUP, reloading external plugins from %s", l.dir)
+				if _, err := l.ReloadPlugins(); err != nil {
+					log.Errorf("failed to reload external plugins: %v", err)
+				}
+			case <-l.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops watching SIGHUP and removes loadDir, the private copies of every .so this loader
+// has opened. Safe to call even if LoadExternalPlugins was never called.
+func (l *ExternalLoader) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sighupCh != nil {
+		signal.Stop(l.sighupCh)
+		close(l.stop)
+		l.sighupCh = nil
+	}
+
+	if l.loadDir != "" {
+		os.RemoveAll(l.loadDir)
+		l.loadDir = ""
+	}
+}
+
+// scan walks l.dir, opens each file matching externalPluginFilePattern, and caches the result.
+func (l *ExternalLoader) scan() (runpluginutil.PluginRegistry, error) {
+	log := l.context.Log()
+
+	entries, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan external plugin directory %s: %v", l.dir, err)
+	}
+
+	registry := runpluginutil.PluginRegistry{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := externalPluginFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		path := filepath.Join(l.dir, entry.Name())
+		modTime := entry.ModTime()
+
+		l.mu.RLock()
+		cached, ok := l.plugins[name]
+		l.mu.RUnlock()
+		if ok && cached.path == path && cached.modTime.Equal(modTime) {
+			registry[name] = cached.handler
+			continue
+		}
+
+		loadPath, err := l.stage(name, path, modTime)
+		if err != nil {
+			log.Errorf("failed to stage external plugin %s from %s: %v", name, path, err)
+			notifyLoadResult(name, err)
+			continue
+		}
+
+		handler, err := l.open(name, loadPath)
+		if err != nil {
+			log.Errorf("failed to load external plugin %s from %s: %v", name, path, err)
+			notifyLoadResult(name, err)
+			continue
+		}
+
+		l.mu.Lock()
+		previous := l.plugins[name]
+		l.plugins[name] = &wrapPlugin{handler: handler, path: path, modTime: modTime, loadPath: loadPath}
+		l.mu.Unlock()
+
+		if previous != nil && previous.loadPath != "" {
+			os.Remove(previous.loadPath)
+		}
+
+		registry[name] = handler
+		notifyLoadResult(name, nil)
+	}
+
+	return registry, nil
+}
+
+// stage copies src into loadDir under a name unique to modTime, so plugin.Open is always asked
+// to open a path it hasn't seen before - see the comment on ExternalLoader.loadDir.
+func (l *ExternalLoader) stage(name, src string, modTime time.Time) (string, error) {
+	l.mu.Lock()
+	if l.loadDir == "" {
+		dir, err := ioutil.TempDir("", "ssm-external-plugins-")
+		if err != nil {
+			l.mu.Unlock()
+			return "", fmt.Errorf("failed to create staging directory: %v", err)
+		}
+		l.loadDir = dir
+	}
+	loadDir := l.loadDir
+	l.mu.Unlock()
+
+	contents, err := ioutil.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read plugin file: %v", err)
+	}
+
+	loadPath := filepath.Join(loadDir, fmt.Sprintf("%s_%d_plugin.so", name, modTime.UnixNano()))
+	if err := ioutil.WriteFile(loadPath, contents, 0600); err != nil {
+		return "", fmt.Errorf("failed to stage plugin file: %v", err)
+	}
+
+	return loadPath, nil
+}
+
+// open loads a single staged .so file and looks up its required NewSSMPlugin symbol.
+func (l *ExternalLoader) open(name, path string) (runpluginutil.T, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin file: %v", err)
+	}
+
+	sym, err := p.Lookup(newSSMPluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin is missing required symbol %s: %v", newSSMPluginSymbol, err)
+	}
+
+	newPlugin, ok := sym.(NewSSMPluginFunc)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s does not match the expected signature", newSSMPluginSymbol)
+	}
+
+	handler, err := newPlugin(l.context, pluginutil.DefaultPluginConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct plugin: %v", err)
+	}
+
+	return handler, nil
+}
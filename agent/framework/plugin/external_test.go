@@ -0,0 +1,104 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalPluginFilePatternMatchesNameAndSuffix(t *testing.T) {
+	match := externalPluginFilePattern.FindStringSubmatch("mydomainjoin_plugin.so")
+	if assert.NotNil(t, match) {
+		assert.Equal(t, "mydomainjoin", match[1])
+	}
+
+	assert.Nil(t, externalPluginFilePattern.FindStringSubmatch("mydomainjoin.so"))
+	assert.Nil(t, externalPluginFilePattern.FindStringSubmatch("mydomainjoin_plugin.so.bak"))
+}
+
+// newTestExternalLoader builds an ExternalLoader without a real context.T, for tests that only
+// exercise the staging/caching logic and never reach the code paths (open, watchSIGHUP) that
+// actually dereference it.
+func newTestExternalLoader(dir string) *ExternalLoader {
+	return &ExternalLoader{
+		dir:     dir,
+		plugins: map[string]*wrapPlugin{},
+	}
+}
+
+func TestStageCopiesIntoAPrivateLoadDirAndIsUnique(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-plugin-src-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "widget_plugin.so")
+	assert.NoError(t, ioutil.WriteFile(src, []byte("version one"), 0600))
+
+	l := newTestExternalLoader(dir)
+	defer l.Close()
+
+	modTime1 := time.Unix(100, 0)
+	loadPath1, err := l.stage("widget", src, modTime1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, src, loadPath1, "stage must not hand plugin.Open the original path")
+
+	content, err := ioutil.ReadFile(loadPath1)
+	assert.NoError(t, err)
+	assert.Equal(t, "version one", string(content))
+
+	// A rebuild - new content, new modTime - must stage to a path that's never been seen before,
+	// since plugin.Open caches by path and would otherwise serve the stale version.
+	assert.NoError(t, ioutil.WriteFile(src, []byte("version two"), 0600))
+	modTime2 := time.Unix(200, 0)
+	loadPath2, err := l.stage("widget", src, modTime2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, loadPath1, loadPath2)
+
+	content, err = ioutil.ReadFile(loadPath2)
+	assert.NoError(t, err)
+	assert.Equal(t, "version two", string(content))
+}
+
+func TestCloseRemovesStagingDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-plugin-src-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "widget_plugin.so")
+	assert.NoError(t, ioutil.WriteFile(src, []byte("content"), 0600))
+
+	l := newTestExternalLoader(dir)
+	loadPath, err := l.stage("widget", src, time.Unix(1, 0))
+	assert.NoError(t, err)
+
+	l.Close()
+
+	_, err = os.Stat(loadPath)
+	assert.True(t, os.IsNotExist(err), "Close should remove every staged copy along with loadDir")
+}
+
+func TestCloseIsSafeWithoutLoadExternalPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-plugin-src-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	l := newTestExternalLoader(dir)
+	assert.NotPanics(t, func() { l.Close() })
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHealthCheckFailed is the error attached to the EventFailed event published when a plugin's
+// health check fails.
+var ErrHealthCheckFailed = errors.New("plugin health check failed")
+
+// HealthChecker is implemented by plugins that can report on their own operational readiness,
+// e.g. lrpminvoker/CloudWatch confirming its backend is reachable, dockercontainer confirming
+// the Docker daemon responds, or inventory confirming WMI/dpkg is available on this host.
+// Plugins that don't implement HealthChecker are assumed healthy for as long as they're enabled.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+const (
+	// defaultHealthCheckInterval is how often a healthy plugin is re-checked.
+	defaultHealthCheckInterval = time.Minute
+
+	// maxHealthCheckInterval caps the exponential backoff applied between checks of a failing plugin.
+	maxHealthCheckInterval = 10 * time.Minute
+
+	// maxConsecutiveFailures is how many checks in a row must fail before a plugin is auto-disabled.
+	maxConsecutiveFailures = 3
+)
+
+// healthState tracks the rolling health-check outcome for a single plugin. nextCheckDue is what
+// actually gates whether a given pass checks this plugin at all - nextInterval on its own is
+// just the duration used to compute the next nextCheckDue once a check runs.
+type healthState struct {
+	consecutiveFailures int
+	nextInterval        time.Duration
+	nextCheckDue        time.Time
+}
+
+// pollTick is how often monitor wakes up to see which plugins are due. It must be small
+// relative to defaultHealthCheckInterval so a plugin backed off to maxHealthCheckInterval is
+// actually skipped on the passes in between, rather than checked every pass regardless.
+const pollTick = 5 * time.Second
+
+// monitor runs health checks against every HealthChecker-implementing plugin installed on m,
+// enabled or disabled, disabling a plugin after maxConsecutiveFailures in a row and re-enabling it automatically
+// once it starts passing again. Each plugin is only checked once its own nextCheckDue has
+// elapsed, so a plugin backed off after repeated failures is actually checked less often, not
+// just assigned a longer nextInterval that the loop never honors. An external call to m.Enable
+// (e.g. from ssm-cli) bypasses nextCheckDue for that plugin's very next pass, via
+// consumeForceRecheck, so a manual re-enable doesn't sit out whatever backoff was in effect when
+// it was disabled. It runs until ctx is cancelled.
+func (m *Manager) monitor(ctx context.Context) {
+	states := map[string]*healthState{}
+
+	for {
+		m.checkPlugins(ctx, states)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollTick):
+		}
+	}
+}
+
+// checkPlugins runs a single pass of monitor's loop body: it finds every installed
+// HealthChecker-implementing plugin, disabled ones included, checks the ones whose nextCheckDue has elapsed (or whose
+// force-recheck flag is set), and updates states in place. Split out from monitor so the
+// backoff/disable-after-N-failures/force-recheck logic can be driven directly from a test without
+// waiting on pollTick/real timers.
+func (m *Manager) checkPlugins(ctx context.Context, states map[string]*healthState) {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.plugins))
+	for name, p := range m.plugins {
+		if _, ok := p.handler.(HealthChecker); ok {
+			names = append(names, name)
+		}
+	}
+	m.mu.RUnlock()
+
+	now := time.Now()
+
+	for _, name := range names {
+		state, ok := states[name]
+		if !ok {
+			state = &healthState{nextInterval: defaultHealthCheckInterval}
+			states[name] = state
+		}
+
+		if now.Before(state.nextCheckDue) && !m.consumeForceRecheck(name) {
+			continue
+		}
+
+		m.mu.RLock()
+		p, ok := m.plugins[name]
+		m.mu.RUnlock()
+		if !ok {
+			delete(states, name)
+			continue
+		}
+
+		checker := p.handler.(HealthChecker)
+		if err := checker.CheckHealth(ctx); err != nil {
+			state.consecutiveFailures++
+			state.nextInterval = backoff(state.nextInterval)
+			publish(Event{Type: EventFailed, PluginName: name, Timestamp: time.Now(), Err: ErrHealthCheckFailed})
+
+			if state.consecutiveFailures >= maxConsecutiveFailures {
+				// Disable publishes EventDisabled itself; no need to publish again here.
+				m.Disable(name)
+			}
+		} else {
+			if state.consecutiveFailures > 0 {
+				// Enable publishes EventEnabled itself. It also queues a force-recheck, so the
+				// plugin is checked again on the next pass regardless of nextCheckDue.
+				m.Enable(name)
+			}
+			state.consecutiveFailures = 0
+			state.nextInterval = defaultHealthCheckInterval
+		}
+
+		state.nextCheckDue = time.Now().Add(state.nextInterval)
+	}
+}
+
+// backoff doubles interval up to maxHealthCheckInterval.
+func backoff(interval time.Duration) time.Duration {
+	next := interval * 2
+	if next > maxHealthCheckInterval {
+		return maxHealthCheckInterval
+	}
+	return next
+}
+
+// StartHealthMonitor launches the background health-check loop for m. Callers should cancel
+// ctx to stop monitoring, typically on agent shutdown.
+func (m *Manager) StartHealthMonitor(ctx context.Context) {
+	go m.monitor(ctx)
+}
@@ -0,0 +1,133 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	agentcontext "github.com/aws/amazon-ssm-agent/agent/context"
+	"github.com/aws/amazon-ssm-agent/agent/contracts"
+	"github.com/aws/amazon-ssm-agent/agent/framework/docmanager/iohandler"
+	"github.com/aws/amazon-ssm-agent/agent/task"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHealthCheckPlugin is a minimal runpluginutil.T/HealthChecker double: Execute is never
+// exercised by these tests, only CheckHealth, whose result is controlled via healthErr.
+type fakeHealthCheckPlugin struct {
+	mu        sync.Mutex
+	healthErr error
+}
+
+func (f *fakeHealthCheckPlugin) Execute(agentcontext.T, contracts.Configuration, task.CancelFlag, iohandler.IOHandler) {
+}
+
+func (f *fakeHealthCheckPlugin) CheckHealth(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.healthErr
+}
+
+func (f *fakeHealthCheckPlugin) setHealthErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthErr = err
+}
+
+func newHealthTestManager(name string, checker *fakeHealthCheckPlugin) *Manager {
+	m := &Manager{
+		plugins:      map[string]*record{},
+		forceRecheck: map[string]struct{}{},
+	}
+	m.plugins[name] = &record{handler: checker, state: StateEnabled, installedAt: time.Now()}
+	return m
+}
+
+func TestCheckPluginsDisablesAfterMaxConsecutiveFailures(t *testing.T) {
+	checker := &fakeHealthCheckPlugin{healthErr: ErrHealthCheckFailed}
+	m := newHealthTestManager("pluginA", checker)
+	states := map[string]*healthState{}
+
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		m.checkPlugins(context.Background(), states)
+		// Force the next iteration's check to run immediately instead of waiting out
+		// nextInterval, so the test doesn't depend on real time passing.
+		states["pluginA"].nextCheckDue = time.Time{}
+	}
+
+	info, err := m.Inspect("pluginA")
+	assert.NoError(t, err)
+	assert.Equal(t, StateDisabled, info.State, "plugin should be disabled after maxConsecutiveFailures")
+	assert.Equal(t, maxConsecutiveFailures, states["pluginA"].consecutiveFailures)
+}
+
+func TestCheckPluginsReEnablesOnceHealthyAgain(t *testing.T) {
+	checker := &fakeHealthCheckPlugin{healthErr: ErrHealthCheckFailed}
+	m := newHealthTestManager("pluginA", checker)
+	states := map[string]*healthState{}
+
+	for i := 0; i < maxConsecutiveFailures; i++ {
+		m.checkPlugins(context.Background(), states)
+		states["pluginA"].nextCheckDue = time.Time{}
+	}
+	info, _ := m.Inspect("pluginA")
+	assert.Equal(t, StateDisabled, info.State)
+
+	checker.setHealthErr(nil)
+	m.checkPlugins(context.Background(), states)
+
+	info, err := m.Inspect("pluginA")
+	assert.NoError(t, err)
+	assert.Equal(t, StateEnabled, info.State)
+	assert.Equal(t, 0, states["pluginA"].consecutiveFailures)
+}
+
+func TestCheckPluginsSkipsPluginNotYetDue(t *testing.T) {
+	checker := &fakeHealthCheckPlugin{healthErr: nil}
+	m := newHealthTestManager("pluginA", checker)
+	states := map[string]*healthState{
+		"pluginA": {nextCheckDue: time.Now().Add(time.Hour)},
+	}
+
+	checker.setHealthErr(ErrHealthCheckFailed)
+	m.checkPlugins(context.Background(), states)
+
+	assert.Equal(t, 0, states["pluginA"].consecutiveFailures, "a not-yet-due plugin should not be checked")
+}
+
+func TestCheckPluginsForceRecheckBypassesNextCheckDue(t *testing.T) {
+	checker := &fakeHealthCheckPlugin{healthErr: ErrHealthCheckFailed}
+	m := newHealthTestManager("pluginA", checker)
+	states := map[string]*healthState{
+		"pluginA": {nextCheckDue: time.Now().Add(time.Hour)},
+	}
+
+	m.forceRecheck["pluginA"] = struct{}{}
+	m.checkPlugins(context.Background(), states)
+
+	assert.Equal(t, 1, states["pluginA"].consecutiveFailures, "a forced recheck should run even though nextCheckDue hasn't elapsed")
+	assert.False(t, m.consumeForceRecheck("pluginA"), "checkPlugins should have consumed the force-recheck flag")
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	interval := defaultHealthCheckInterval
+	for i := 0; i < 20; i++ {
+		interval = backoff(interval)
+		assert.LessOrEqual(t, interval, maxHealthCheckInterval)
+	}
+	assert.Equal(t, maxHealthCheckInterval, interval)
+}
@@ -0,0 +1,214 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+)
+
+// State represents the lifecycle state of a registered plugin.
+type State string
+
+const (
+	// StateEnabled means the plugin is registered and eligible for execution.
+	StateEnabled State = "Enabled"
+	// StateDisabled means the plugin is registered but will be skipped by the executer.
+	StateDisabled State = "Disabled"
+)
+
+// Info describes the current status of a single plugin, as reported by Inspect and List.
+type Info struct {
+	Name        string
+	State       State
+	InstalledAt time.Time
+}
+
+// record is the manager's internal bookkeeping for a single plugin.
+type record struct {
+	handler     runpluginutil.T
+	state       State
+	installedAt time.Time
+}
+
+// Manager is a runtime-mutable view over a plugin registry. Unlike the old cache/sync.RWMutex
+// pair behind RegisteredWorkerPlugins and RegisteredLongRunningPlugins, which was populated once
+// at agent boot and cached forever, a Manager lets operators and SSM documents install, enable,
+// disable, and remove individual plugins - worker or long-running - without an agent restart.
+// RegisteredWorkerPlugins and RegisteredLongRunningPlugins are themselves now backed by a
+// Manager each; see plugin.go.
+type Manager struct {
+	mu      sync.RWMutex
+	plugins map[string]*record
+
+	forceMu      sync.Mutex
+	forceRecheck map[string]struct{}
+}
+
+// newManager creates a Manager seeded from an already-loaded registry (the result of
+// loadWorkerPlugins or loadLongRunningPlugins), with every seeded plugin starting out Enabled.
+func newManager(seed runpluginutil.PluginRegistry) *Manager {
+	m := &Manager{
+		plugins:      map[string]*record{},
+		forceRecheck: map[string]struct{}{},
+	}
+
+	now := time.Now()
+	for name, handler := range seed {
+		m.plugins[name] = &record{
+			handler:     handler,
+			state:       StateEnabled,
+			installedAt: now,
+		}
+	}
+
+	return m
+}
+
+// Install registers a new plugin handler under name, or replaces an existing one. The plugin
+// starts out Enabled. Installing a plugin whose name is unknown to this version of the agent
+// (not present in allPlugins) is still permitted, since Install is also how external/site-specific
+// plugins are expected to be added.
+func (m *Manager) Install(name string, handler runpluginutil.T) error {
+	if handler == nil {
+		return fmt.Errorf("cannot install plugin %s: handler is nil", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.plugins[name] = &record{
+		handler:     handler,
+		state:       StateEnabled,
+		installedAt: time.Now(),
+	}
+
+	return nil
+}
+
+// Remove unregisters a plugin entirely. Subsequent documents referencing name will be treated
+// as unsupported, the same as if the plugin had never been installed.
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.plugins[name]; !ok {
+		return fmt.Errorf("plugin %s is not installed", name)
+	}
+
+	delete(m.plugins, name)
+	return nil
+}
+
+// Enable marks an installed plugin as eligible for execution, publishes EventEnabled, and - if
+// a health monitor is running against m (see health.go) - forces that plugin's next health
+// check to happen immediately instead of waiting out whatever backoff interval was in effect.
+func (m *Manager) Enable(name string) error {
+	m.mu.Lock()
+	p, ok := m.plugins[name]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("plugin %s is not installed", name)
+	}
+	p.state = StateEnabled
+	m.mu.Unlock()
+
+	m.forceMu.Lock()
+	if m.forceRecheck == nil {
+		m.forceRecheck = map[string]struct{}{}
+	}
+	m.forceRecheck[name] = struct{}{}
+	m.forceMu.Unlock()
+
+	publish(Event{Type: EventEnabled, PluginName: name, Timestamp: time.Now()})
+	return nil
+}
+
+// Disable marks an installed plugin as unavailable without removing it, and publishes
+// EventDisabled. The executer should treat a disabled plugin the same way it treats an
+// unsupported one: skipped, not failed.
+func (m *Manager) Disable(name string) error {
+	m.mu.Lock()
+	p, ok := m.plugins[name]
+	if !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("plugin %s is not installed", name)
+	}
+	p.state = StateDisabled
+	m.mu.Unlock()
+
+	publish(Event{Type: EventDisabled, PluginName: name, Timestamp: time.Now()})
+	return nil
+}
+
+// consumeForceRecheck reports whether name was queued for an immediate health recheck by
+// Enable, clearing the flag as it's consumed so it only forces the very next check.
+func (m *Manager) consumeForceRecheck(name string) bool {
+	m.forceMu.Lock()
+	defer m.forceMu.Unlock()
+
+	if _, ok := m.forceRecheck[name]; !ok {
+		return false
+	}
+
+	delete(m.forceRecheck, name)
+	return true
+}
+
+// Inspect returns the current status of a single plugin.
+func (m *Manager) Inspect(name string) (Info, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.plugins[name]
+	if !ok {
+		return Info{}, fmt.Errorf("plugin %s is not installed", name)
+	}
+
+	return Info{Name: name, State: p.state, InstalledAt: p.installedAt}, nil
+}
+
+// List returns the status of every installed plugin, sorted by name for stable `ssm-cli` output.
+func (m *Manager) List() []Info {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]Info, 0, len(m.plugins))
+	for name, p := range m.plugins {
+		infos = append(infos, Info{Name: name, State: p.state, InstalledAt: p.installedAt})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	return infos
+}
+
+// Registry returns a runpluginutil.PluginRegistry containing only the plugins currently Enabled,
+// so the executer can keep consuming the same map-shaped registry it always has.
+func (m *Manager) Registry() runpluginutil.PluginRegistry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	registry := runpluginutil.PluginRegistry{}
+	for name, p := range m.plugins {
+		if p.state == StateEnabled {
+			registry[name] = p.handler
+		}
+	}
+
+	return registry
+}
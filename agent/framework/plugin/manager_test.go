@@ -0,0 +1,105 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ssm-agent/agent/framework/runpluginutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(names ...string) *Manager {
+	seed := runpluginutil.PluginRegistry{}
+	for _, name := range names {
+		seed[name] = nil
+	}
+	return newManager(seed)
+}
+
+func TestDisableMarksPluginUnavailableAndPublishesEvent(t *testing.T) {
+	m := newTestManager("pluginA")
+
+	sub := Events("pluginA", EventDisabled)
+	defer sub.Close()
+
+	assert.NoError(t, m.Disable("pluginA"))
+
+	info, err := m.Inspect("pluginA")
+	assert.NoError(t, err)
+	assert.Equal(t, StateDisabled, info.State)
+	assert.NotContains(t, m.Registry(), "pluginA")
+
+	select {
+	case evt := <-sub.C():
+		assert.Equal(t, EventDisabled, evt.Type)
+		assert.Equal(t, "pluginA", evt.PluginName)
+	case <-time.After(time.Second):
+		t.Fatal("expected Disable to publish EventDisabled")
+	}
+}
+
+func TestEnableMarksPluginAvailableAndPublishesEvent(t *testing.T) {
+	m := newTestManager("pluginA")
+	assert.NoError(t, m.Disable("pluginA"))
+
+	sub := Events("pluginA", EventEnabled)
+	defer sub.Close()
+
+	assert.NoError(t, m.Enable("pluginA"))
+
+	info, err := m.Inspect("pluginA")
+	assert.NoError(t, err)
+	assert.Equal(t, StateEnabled, info.State)
+	assert.Contains(t, m.Registry(), "pluginA")
+
+	select {
+	case evt := <-sub.C():
+		assert.Equal(t, EventEnabled, evt.Type)
+		assert.Equal(t, "pluginA", evt.PluginName)
+	case <-time.After(time.Second):
+		t.Fatal("expected Enable to publish EventEnabled")
+	}
+}
+
+func TestEnableQueuesAForceRecheckThatIsConsumedOnce(t *testing.T) {
+	m := newTestManager("pluginA")
+
+	assert.False(t, m.consumeForceRecheck("pluginA"), "no recheck should be queued before Enable")
+
+	assert.NoError(t, m.Enable("pluginA"))
+
+	assert.True(t, m.consumeForceRecheck("pluginA"), "Enable should queue a forced recheck")
+	assert.False(t, m.consumeForceRecheck("pluginA"), "a forced recheck should only fire once")
+}
+
+func TestEnableDisableOnUnknownPluginReturnsError(t *testing.T) {
+	m := newTestManager()
+
+	assert.Error(t, m.Enable("does-not-exist"))
+	assert.Error(t, m.Disable("does-not-exist"))
+}
+
+func TestListAndRegistryReflectState(t *testing.T) {
+	m := newTestManager("pluginA", "pluginB")
+	assert.NoError(t, m.Disable("pluginB"))
+
+	registry := m.Registry()
+	assert.Contains(t, registry, "pluginA")
+	assert.NotContains(t, registry, "pluginB")
+
+	infos := m.List()
+	assert.Len(t, infos, 2)
+}
@@ -16,6 +16,7 @@
 package plugin
 
 import (
+	stdcontext "context"
 	"sync"
 
 	"github.com/aws/amazon-ssm-agent/agent/appconfig"
@@ -52,50 +53,55 @@ var allPlugins = map[string]struct{}{
 	appconfig.PluginNameRefreshAssociation:     {},
 }
 
-// registeredExecuters stores the registered plugins.
-var registeredExecuters, registeredLongRunningPlugins *runpluginutil.PluginRegistry
+// workerManager and longRunningManager back RegisteredWorkerPlugins and
+// RegisteredLongRunningPlugins respectively. They're built once, lazily, from the same
+// loadWorkerPlugins/loadLongRunningPlugins this package has always used to populate them -
+// but as Managers rather than frozen maps, so ssm-cli (via WorkerPluginManager/
+// LongRunningPluginManager below) can Install/Enable/Disable/Remove/Inspect/List at runtime.
+var (
+	managersOnce       sync.Once
+	workerManager      *Manager
+	longRunningManager *Manager
+)
+
+// ensureManagers builds workerManager and longRunningManager on first use and starts each one's
+// health monitor, so auto-disable-on-failure actually runs against the plugins the agent loaded
+// rather than existing only as dead code. The monitors run for the lifetime of the process; there
+// is currently no agent shutdown hook to cancel them, the same as every other goroutine this
+// package has always started at load time.
+func ensureManagers(context context.T) {
+	managersOnce.Do(func() {
+		workerManager = newManager(loadWorkerPlugins(context))
+		longRunningManager = newManager(loadLongRunningPlugins(context))
+
+		workerManager.StartHealthMonitor(stdcontext.Background())
+		longRunningManager.StartHealthMonitor(stdcontext.Background())
+	})
+}
 
 // RegisteredWorkerPlugins returns all registered core modules.
 func RegisteredWorkerPlugins(context context.T) runpluginutil.PluginRegistry {
-	if !isLoaded() {
-		cache(loadWorkerPlugins(context), loadLongRunningPlugins(context))
-	}
-	return getCachedWorkerPlugins()
+	ensureManagers(context)
+	return workerManager.Registry()
 }
 
-// LongRunningPlugins returns a map of long running plugins and their respective handlers
+// RegisteredLongRunningPlugins returns a map of long running plugins and their respective handlers
 func RegisteredLongRunningPlugins(context context.T) runpluginutil.PluginRegistry {
-	if !isLoaded() {
-		cache(loadWorkerPlugins(context), loadLongRunningPlugins(context))
-	}
-	return getCachedLongRunningPlugins()
-}
-
-var lock sync.RWMutex
-
-func isLoaded() bool {
-	lock.RLock()
-	defer lock.RUnlock()
-	return registeredExecuters != nil
-}
-
-func cache(workerPlugins, longRunningPlugins runpluginutil.PluginRegistry) {
-	lock.Lock()
-	defer lock.Unlock()
-	registeredExecuters = &workerPlugins
-	registeredLongRunningPlugins = &longRunningPlugins
+	ensureManagers(context)
+	return longRunningManager.Registry()
 }
 
-func getCachedWorkerPlugins() runpluginutil.PluginRegistry {
-	lock.RLock()
-	defer lock.RUnlock()
-	return *registeredExecuters
+// WorkerPluginManager returns the Manager backing RegisteredWorkerPlugins, for callers (e.g.
+// ssm-cli) that need Install/Enable/Disable/Remove/Inspect/List rather than just the registry.
+func WorkerPluginManager(context context.T) *Manager {
+	ensureManagers(context)
+	return workerManager
 }
 
-func getCachedLongRunningPlugins() runpluginutil.PluginRegistry {
-	lock.RLock()
-	defer lock.RUnlock()
-	return *registeredLongRunningPlugins
+// LongRunningPluginManager returns the Manager backing RegisteredLongRunningPlugins.
+func LongRunningPluginManager(context context.T) *Manager {
+	ensureManagers(context)
+	return longRunningManager
 }
 
 // loadLongRunningPlugins loads all long running plugins
@@ -109,9 +115,11 @@ func loadLongRunningPlugins(context context.T) runpluginutil.PluginRegistry {
 	//NOTE: register all long running plugins here (one instance of lrpminvoker per long running plugin)
 	if handler, err := lrpminvoker.NewPlugin(pluginutil.DefaultPluginConfig(), appconfig.PluginNameCloudWatch); err != nil {
 		log.Errorf("Failed to load lrpminvoker that will handle all long running plugins - %v", err)
+		notifyLoadResult(appconfig.PluginNameCloudWatch, err)
 	} else {
 		//registering handler for aws:cloudWatch plugin
 		longRunningPlugins[appconfig.PluginNameCloudWatch] = handler
+		notifyLoadResult(appconfig.PluginNameCloudWatch, nil)
 	}
 
 	return longRunningPlugins
@@ -140,8 +148,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	inventoryPluginName := inventory.Name()
 	if inventoryPlugin, err := inventory.NewPlugin(context, pluginutil.DefaultPluginConfig()); err != nil {
 		log.Errorf("failed to create plugin %s %v", inventoryPluginName, err)
+		notifyLoadResult(inventoryPluginName, err)
 	} else {
 		workerPlugins[inventoryPluginName] = inventoryPlugin
+		notifyLoadResult(inventoryPluginName, nil)
 	}
 
 	// registering aws:runPowerShellScript plugin
@@ -149,8 +159,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	powershellPluginName := powershellPlugin.Name
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", powershellPluginName, err)
+		notifyLoadResult(powershellPluginName, err)
 	} else {
 		workerPlugins[powershellPluginName] = powershellPlugin
+		notifyLoadResult(powershellPluginName, nil)
 	}
 
 	// registering aws:updateSsmAgent plugin
@@ -158,8 +170,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	updateAgentPlugin, err := updatessmagent.NewPlugin(updatessmagent.GetUpdatePluginConfig(context))
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", updateAgentPluginName, err)
+		notifyLoadResult(updateAgentPluginName, err)
 	} else {
 		workerPlugins[updateAgentPluginName] = updateAgentPlugin
+		notifyLoadResult(updateAgentPluginName, nil)
 	}
 
 	// registering aws:configureContainers plugin
@@ -167,8 +181,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	configureContainersPlugin, err := configurecontainers.NewPlugin(pluginutil.DefaultPluginConfig())
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", configureContainersPluginName, err)
+		notifyLoadResult(configureContainersPluginName, err)
 	} else {
 		workerPlugins[configureContainersPluginName] = configureContainersPlugin
+		notifyLoadResult(configureContainersPluginName, nil)
 	}
 
 	// registering aws:runDockerAction plugin
@@ -176,8 +192,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	runDockerPlugin, err := dockercontainer.NewPlugin(pluginutil.DefaultPluginConfig())
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", runDockerPluginName, err)
+		notifyLoadResult(runDockerPluginName, err)
 	} else {
 		workerPlugins[runDockerPluginName] = runDockerPlugin
+		notifyLoadResult(runDockerPluginName, nil)
 	}
 
 	// registering aws:refreshAssociation plugin
@@ -185,8 +203,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	refreshAssociationPlugin, err := refreshassociation.NewPlugin(pluginutil.DefaultPluginConfig())
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", refreshAssociationPluginName, err)
+		notifyLoadResult(refreshAssociationPluginName, err)
 	} else {
 		workerPlugins[refreshAssociationPluginName] = refreshAssociationPlugin
+		notifyLoadResult(refreshAssociationPluginName, nil)
 	}
 
 	// registering aws:configurePackage
@@ -194,8 +214,10 @@ func loadPlatformIndependentPlugins(context context.T) runpluginutil.PluginRegis
 	configurePackagePlugin, err := configurepackage.NewPlugin(pluginutil.DefaultPluginConfig())
 	if err != nil {
 		log.Errorf("failed to create plugin %s %v", configurePackagePluginName, err)
+		notifyLoadResult(configurePackagePluginName, err)
 	} else {
 		workerPlugins[configurePackagePluginName] = configurePackagePlugin
+		notifyLoadResult(configurePackagePluginName, nil)
 	}
 
 	return workerPlugins
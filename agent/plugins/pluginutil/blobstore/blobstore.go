@@ -0,0 +1,201 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package blobstore provides a content-addressable store shared by plugins, such as
+// configurepackage and updatessmagent, that need to fetch and verify arbitrary payloads.
+// Blobs are keyed by the SHA-256 digest of their content, so two packages that happen to
+// ship the same bytes (e.g. identical layers across versions) are only ever stored once,
+// and a caller can always confirm it received exactly the bytes it asked for.
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Digest identifies a blob by the hex-encoded SHA-256 of its content, in "sha256:<hex>" form.
+type Digest string
+
+// digestPattern is the only shape of Digest this package will turn into a filesystem path.
+// Digests reach Store.path via Manifest.Layers, i.e. parsed out of a fetched manifest, so a
+// malformed one (too short, or carrying ".." path-traversal segments) must be rejected before
+// it's ever joined onto the store root.
+var digestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// Descriptor points at a single blob within a manifest.
+type Descriptor struct {
+	Digest    Digest
+	Size      int64
+	MediaType string
+	URL       string
+}
+
+// Manifest describes a single installable version of a plugin package as a set of
+// content-addressed layers plus a config blob, mirroring how the package is distributed.
+type Manifest struct {
+	Name    string
+	Version string
+	Layers  []Descriptor
+	Config  Descriptor
+}
+
+// Store is a content-addressable blob store rooted at a directory on disk. Blobs are written
+// to a temp file and atomically renamed into place, so a reader never observes a partially
+// written blob and concurrent Put calls for the same content are safe.
+type Store struct {
+	root string
+}
+
+// NewStore creates a Store rooted at dir, creating the directory if it does not already exist.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create blob store at %s: %v", dir, err)
+	}
+
+	return &Store{root: dir}, nil
+}
+
+// Has reports whether digest is already present in the store.
+func (s *Store) Has(digest Digest) bool {
+	path, err := s.path(digest)
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Get returns a reader for the blob identified by digest. The returned reader hashes the blob
+// as it is read and fails closed: if the content on disk no longer hashes to digest, Read
+// returns an error instead of serving the corrupted/tampered bytes, since these blobs go on to
+// run as root.
+func (s *Store) Get(digest Digest) (io.ReadCloser, error) {
+	path, err := s.path(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %v", digest, err)
+	}
+
+	return &verifyingReader{r: f, c: f, hasher: sha256.New(), expected: digest}, nil
+}
+
+// verifyingReader hashes everything read through it and, once the underlying reader is
+// exhausted, compares the running hash against expected - failing the final Read if they
+// don't match so a caller can never silently consume a corrupted blob in full.
+type verifyingReader struct {
+	r        io.Reader
+	c        io.Closer
+	hasher   hash.Hash
+	expected Digest
+	verified bool
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	if n > 0 {
+		v.hasher.Write(p[:n])
+	}
+
+	if err == io.EOF && !v.verified {
+		v.verified = true
+		actual := Digest(fmt.Sprintf("sha256:%s", hex.EncodeToString(v.hasher.Sum(nil))))
+		if actual != v.expected {
+			return n, fmt.Errorf("blob %s is corrupted: content hashes to %s", v.expected, actual)
+		}
+	}
+
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	return v.c.Close()
+}
+
+// Put reads r to completion, stores it keyed by the SHA-256 digest of its content, and returns
+// that digest along with the number of bytes written. If a blob with the same digest already
+// exists, Put skips the write and simply returns its digest and size.
+func (s *Store) Put(r io.Reader) (Digest, int64, error) {
+	tmp, err := ioutil.TempFile(s.root, "blob-")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp file in blob store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write blob: %v", err)
+	}
+
+	digest := Digest(fmt.Sprintf("sha256:%s", hex.EncodeToString(hasher.Sum(nil))))
+
+	if s.Has(digest) {
+		return digest, size, nil
+	}
+
+	path, err := s.path(digest)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to finalize blob: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to store blob %s: %v", digest, err)
+	}
+
+	return digest, size, nil
+}
+
+// Verify reads r and confirms it hashes to the expected digest, without storing anything.
+// Callers use this to validate a downloaded layer before unpacking it.
+func Verify(expected Digest, r io.Reader) error {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return fmt.Errorf("failed to read content for verification: %v", err)
+	}
+
+	actual := Digest(fmt.Sprintf("sha256:%s", hex.EncodeToString(hasher.Sum(nil))))
+	if actual != expected {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", expected, actual)
+	}
+
+	return nil
+}
+
+// path returns the on-disk location for digest, after validating that digest is a well-formed
+// "sha256:<64 lowercase hex chars>" string. Digests come from Manifest.Layers, i.e. parsed out
+// of a fetched manifest, so they must be validated before being joined onto the store root -
+// an unvalidated digest is a path-traversal primitive into code that runs as root.
+func (s *Store) path(digest Digest) (string, error) {
+	if !digestPattern.MatchString(string(digest)) {
+		return "", fmt.Errorf("invalid digest %q: must match %s", digest, digestPattern.String())
+	}
+
+	return filepath.Join(s.root, string(digest)[len("sha256:"):]), nil
+}
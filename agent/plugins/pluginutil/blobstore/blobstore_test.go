@@ -0,0 +1,119 @@
+// Copyright 2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the
+// License is located at
+//
+// http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+// either express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "blobstore-test-")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	store, err := NewStore(dir)
+	if !assert.NoError(t, err) {
+		os.RemoveAll(dir)
+		t.FailNow()
+	}
+
+	return store, func() { os.RemoveAll(dir) }
+}
+
+func TestPutThenGetRoundTrips(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	digest, size, err := store.Put(bytes.NewReader([]byte("hello world")))
+	assert.NoError(t, err)
+	assert.EqualValues(t, len("hello world"), size)
+	assert.True(t, store.Has(digest))
+
+	r, err := store.Get(digest)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+}
+
+func TestPutIsIdempotentForIdenticalContent(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	digest1, _, err := store.Put(bytes.NewReader([]byte("same bytes")))
+	assert.NoError(t, err)
+
+	digest2, _, err := store.Put(bytes.NewReader([]byte("same bytes")))
+	assert.NoError(t, err)
+
+	assert.Equal(t, digest1, digest2)
+}
+
+func TestGetRejectsCorruptedContent(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	digest, _, err := store.Put(bytes.NewReader([]byte("original content")))
+	assert.NoError(t, err)
+
+	path, err := store.path(digest)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("tampered content"), 0600))
+
+	r, err := store.Get(digest)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	_, err = ioutil.ReadAll(r)
+	assert.Error(t, err)
+}
+
+func TestPathRejectsMalformedDigests(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	malformed := []Digest{
+		"",
+		"not-a-digest",
+		"sha256:tooshort",
+		"sha256:" + Digest(bytes.Repeat([]byte("g"), 64)), // non-hex characters
+		"sha256:../../../../etc/passwd",
+		Digest(bytes.Repeat([]byte("a"), 64)), // missing the "sha256:" prefix
+	}
+
+	for _, digest := range malformed {
+		_, err := store.path(digest)
+		assert.Errorf(t, err, "expected digest %q to be rejected", digest)
+		assert.False(t, store.Has(digest))
+	}
+}
+
+func TestVerifyDetectsMismatch(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	digest, _, err := store.Put(bytes.NewReader([]byte("trusted content")))
+	assert.NoError(t, err)
+
+	assert.NoError(t, Verify(digest, bytes.NewReader([]byte("trusted content"))))
+	assert.Error(t, Verify(digest, bytes.NewReader([]byte("different content"))))
+}